@@ -0,0 +1,39 @@
+package create
+
+func init() {
+	tplFiles["api/admin.gen.go"] = adminFileTpl
+}
+
+// adminFileTpl backs api/admin.gen.go: it starts NewAdminMux (metrics.gen.go)
+// on its own listen address at process start, so every generated service
+// gets /metrics, pprof, /healthz and /readyz without any hand-wiring in
+// main.go.
+const adminFileTpl = `package api
+
+import (
+	"net/http"
+
+	tp "github.com/henrylee2cn/teleport"
+)
+
+// AdminConfig controls the admin mux's listen address. Enabled defaults to
+// true so observability works out of the box; set it false to opt out.
+var AdminConfig = struct {
+	Enabled bool
+	Addr    string
+}{
+	Enabled: true,
+	Addr:    ":6060",
+}
+
+func init() {
+	if !AdminConfig.Enabled {
+		return
+	}
+	go func() {
+		if err := http.ListenAndServe(AdminConfig.Addr, NewAdminMux()); err != nil {
+			tp.Fatalf("[micro] admin mux listen on %s: %v", AdminConfig.Addr, err)
+		}
+	}()
+}
+`
@@ -0,0 +1,186 @@
+// Package ddl parses a MySQL `.sql` dump of `CREATE TABLE` statements so
+// `micro gen` can reverse-engineer models from a live schema instead of
+// requiring users to hand-translate columns into the template DSL.
+package ddl
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Table is one parsed `CREATE TABLE` statement.
+	Table struct {
+		Name       string
+		Comment    string
+		Columns    []*Column
+		PrimaryKey []string
+		UniqueKeys [][]string
+		// Raw is the original `CREATE TABLE ...;` statement, embedded
+		// verbatim into args/const.gen.go so `${name}Sql` is runnable.
+		Raw string
+	}
+	// Column is one column definition within a Table.
+	Column struct {
+		Name          string
+		Type          string // raw SQL type, lower-cased, e.g. "varchar", "bigint"
+		Length        int
+		Unsigned      bool
+		NotNull       bool
+		AutoIncrement bool
+		Default       string
+		Comment       string
+	}
+)
+
+var (
+	// createTableRe is matched against one already-split statement at a
+	// time (see splitStatements), so the greedy `.*` only ever spans a
+	// single table's column list instead of swallowing the rest of a
+	// multi-table dump.
+	createTableRe  = regexp.MustCompile(`(?is)^\s*CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?` + "`?(\\w+)`?" + `\s*\((.*)\)\s*([^;]*);?\s*$`)
+	tableCommentRe = regexp.MustCompile(`(?i)COMMENT\s*=?\s*'([^']*)'`)
+
+	columnHeadRe = regexp.MustCompile(`(?is)^` + "`?(\\w+)`?" + `\s+(\w+)(?:\(([^)]*)\))?`)
+	unsignedRe   = regexp.MustCompile(`(?i)\bunsigned\b`)
+	notNullRe    = regexp.MustCompile(`(?i)\bnot\s+null\b`)
+	autoIncrRe   = regexp.MustCompile(`(?i)\bauto_increment\b`)
+	defaultRe    = regexp.MustCompile(`(?i)\bdefault\s+('[^']*'|\S+)`)
+	commentRe    = regexp.MustCompile(`(?i)\bcomment\s+'([^']*)'`)
+
+	primaryKeyRe = regexp.MustCompile(`(?i)^primary\s+key\s*\(([^)]*)\)`)
+	uniqueKeyRe  = regexp.MustCompile(`(?i)^unique\s+(?:key|index)\s*` + "`?\\w*`?" + `\s*\(([^)]*)\)`)
+	keyRe        = regexp.MustCompile(`(?i)^(?:key|index)\s*` + "`?\\w*`?" + `\s*\(([^)]*)\)`)
+)
+
+// Parse parses every `CREATE TABLE` statement in a .sql dump.
+func Parse(src []byte) ([]*Table, error) {
+	var tables []*Table
+	for _, stmt := range splitStatements(string(src)) {
+		m := createTableRe.FindStringSubmatch(stmt)
+		if m == nil {
+			continue
+		}
+		raw, name, body, opts := m[0], m[1], m[2], m[3]
+		t := &Table{Name: name, Raw: strings.TrimSpace(raw)}
+		if cm := tableCommentRe.FindStringSubmatch(opts); cm != nil {
+			t.Comment = cm[1]
+		}
+		for _, part := range splitTopLevel(body) {
+			part = strings.TrimSpace(part)
+			if len(part) == 0 {
+				continue
+			}
+			if pk := primaryKeyRe.FindStringSubmatch(part); pk != nil {
+				t.PrimaryKey = splitIdentList(pk[1])
+				continue
+			}
+			if uk := uniqueKeyRe.FindStringSubmatch(part); uk != nil {
+				t.UniqueKeys = append(t.UniqueKeys, splitIdentList(uk[1]))
+				continue
+			}
+			if keyRe.MatchString(part) {
+				// plain secondary index: not needed to materialize the model
+				continue
+			}
+			col, err := parseColumn(part)
+			if err != nil {
+				return nil, fmt.Errorf("ddl: table %s: %v", name, err)
+			}
+			t.Columns = append(t.Columns, col)
+		}
+		tables = append(tables, t)
+	}
+	return tables, nil
+}
+
+func parseColumn(def string) (*Column, error) {
+	hm := columnHeadRe.FindStringSubmatch(def)
+	if hm == nil {
+		return nil, fmt.Errorf("cannot parse column definition: %s", def)
+	}
+	c := &Column{Name: hm[1], Type: strings.ToLower(hm[2])}
+	if len(hm[3]) > 0 {
+		length := strings.TrimSpace(strings.Split(hm[3], ",")[0])
+		c.Length, _ = strconv.Atoi(length)
+	}
+	c.Unsigned = unsignedRe.MatchString(def)
+	c.NotNull = notNullRe.MatchString(def)
+	c.AutoIncrement = autoIncrRe.MatchString(def)
+	if dm := defaultRe.FindStringSubmatch(def); dm != nil {
+		c.Default = strings.Trim(dm[1], "'")
+	}
+	if cm := commentRe.FindStringSubmatch(def); cm != nil {
+		c.Comment = cm[1]
+	}
+	return c, nil
+}
+
+// splitStatements splits a .sql dump into individual `...;` statements,
+// ignoring semicolons nested inside parens, quoted strings, or backtick
+// identifiers, so a multi-statement dump is parsed one `CREATE TABLE` at a
+// time instead of matching greedily across every statement in the file.
+func splitStatements(s string) []string {
+	var stmts []string
+	depth := 0
+	start := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote && s[i-1] != '\\' {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ';' && depth == 0:
+			stmts = append(stmts, s[start:i+1])
+			start = i + 1
+		}
+	}
+	if len(strings.TrimSpace(s[start:])) > 0 {
+		stmts = append(stmts, s[start:])
+	}
+	return stmts
+}
+
+// splitTopLevel splits a column/constraint list on commas, ignoring commas
+// nested inside parens (e.g. the "10,2" in `decimal(10,2)`).
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth := 0
+	start := 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, s[start:])
+	return parts
+}
+
+func splitIdentList(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		p = strings.Trim(strings.TrimSpace(p), "`")
+		if len(p) > 0 {
+			out = append(out, p)
+		}
+	}
+	return out
+}
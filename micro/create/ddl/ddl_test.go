@@ -0,0 +1,54 @@
+package ddl
+
+import "testing"
+
+func TestParseMultipleTables(t *testing.T) {
+	src := []byte(`
+CREATE TABLE ` + "`user`" + ` (
+	` + "`id`" + ` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key',
+	` + "`name`" + ` varchar(64) NOT NULL DEFAULT '' COMMENT 'user name',
+	PRIMARY KEY (` + "`id`" + `)
+) ENGINE=InnoDB COMMENT='user table';
+
+CREATE TABLE ` + "`order`" + ` (
+	` + "`id`" + ` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key',
+	` + "`price`" + ` decimal(10,2) NOT NULL DEFAULT '0.00' COMMENT 'order price',
+	` + "`user_id`" + ` bigint unsigned NOT NULL COMMENT 'owning user',
+	PRIMARY KEY (` + "`id`" + `),
+	UNIQUE KEY ` + "`uniq_user_id`" + ` (` + "`user_id`" + `)
+) ENGINE=InnoDB COMMENT='order table';
+`)
+
+	tables, err := Parse(src)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(tables) != 2 {
+		t.Fatalf("len(tables) = %d, want 2", len(tables))
+	}
+
+	user := tables[0]
+	if user.Name != "user" {
+		t.Fatalf("tables[0].Name = %q, want %q", user.Name, "user")
+	}
+	if user.Comment != "user table" {
+		t.Fatalf("tables[0].Comment = %q, want %q", user.Comment, "user table")
+	}
+	if len(user.Columns) != 2 {
+		t.Fatalf("len(tables[0].Columns) = %d, want 2", len(user.Columns))
+	}
+
+	order := tables[1]
+	if order.Name != "order" {
+		t.Fatalf("tables[1].Name = %q, want %q", order.Name, "order")
+	}
+	if order.Comment != "order table" {
+		t.Fatalf("tables[1].Comment = %q, want %q", order.Comment, "order table")
+	}
+	if len(order.Columns) != 3 {
+		t.Fatalf("len(tables[1].Columns) = %d, want 3", len(order.Columns))
+	}
+	if len(order.UniqueKeys) != 1 || len(order.UniqueKeys[0]) != 1 || order.UniqueKeys[0][0] != "user_id" {
+		t.Fatalf("tables[1].UniqueKeys = %v, want [[user_id]]", order.UniqueKeys)
+	}
+}
@@ -0,0 +1,93 @@
+package create
+
+// mongoModelTpl generates real mongo-driver code for a `//go:model mongo`
+// struct: CRUD functions backed by bson.M filters built from PrimaryFields,
+// and unique-index registration for UniqueFields, so the mongo model style
+// is a first-class alternative to the mysql path instead of a SQL-shaped
+// copy-paste stub.
+const mongoModelTpl = `package model
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+{{.Doc}}type {{.Name}} struct {
+{{range .Fields}}	{{.Name}} {{.Typ}} ` + "`" + `bson:"{{.ModelName}}"` + "`" + `
+{{end}}}
+
+var {{.LowerFirstName}}Collection *mongo.Collection
+
+// Init{{.Name}}Collection opens the '{{.SnakeName}}' collection once and
+// registers its unique indexes.
+func Init{{.Name}}Collection(db *mongo.Database) error {
+	{{.LowerFirstName}}Collection = db.Collection("{{.SnakeName}}")
+{{if .UniqueFields}}	_, err := {{.LowerFirstName}}Collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+{{range .UniqueFields}}		{Keys: bson.M{"{{.ModelName}}": 1}, Options: options.Index().SetUnique(true)},
+{{end}}	})
+	return err
+{{else}}	return nil
+{{end}}}
+
+// Insert{{.Name}} inserts a {{.Name}} document.
+func Insert{{.Name}}(m *{{.Name}}) error {
+	_, err := {{.LowerFirstName}}Collection.InsertOne(context.Background(), m)
+	return err
+}
+
+// Find{{.Name}}ByID returns the {{.Name}} document matching its primary key.
+func Find{{.Name}}ByID({{.PrimaryArgs}}) (*{{.Name}}, error) {
+	m := new({{.Name}})
+	filter := bson.M{ {{.PrimaryFilter}} }
+	err := {{.LowerFirstName}}Collection.FindOne(context.Background(), filter).Decode(m)
+	return m, err
+}
+
+// Update{{.Name}}ByID applies set to the document matching its primary key.
+func Update{{.Name}}ByID({{.PrimaryArgs}}, set bson.M) error {
+	filter := bson.M{ {{.PrimaryFilter}} }
+	_, err := {{.LowerFirstName}}Collection.UpdateOne(context.Background(), filter, bson.M{"$set": set})
+	return err
+}
+
+// Upsert{{.Name}}ByPrimary inserts m, or replaces the existing document with
+// the same primary key.
+func Upsert{{.Name}}ByPrimary(m *{{.Name}}) error {
+	filter := bson.M{ {{.PrimaryFilterFromM}} }
+	_, err := {{.LowerFirstName}}Collection.ReplaceOne(context.Background(), filter, m, options.Replace().SetUpsert(true))
+	return err
+}
+
+// Delete{{.Name}}ByID removes the document matching its primary key
+{{if .HasDeletedTs}}// by setting deleted_ts rather than actually deleting it.
+{{else}}// .
+{{end}}func Delete{{.Name}}ByID({{.PrimaryArgs}}) error {
+	filter := bson.M{ {{.PrimaryFilter}} }
+{{if .HasDeletedTs}}	_, err := {{.LowerFirstName}}Collection.UpdateOne(context.Background(), filter, bson.M{"$set": bson.M{"deleted_ts": time.Now().Unix()}})
+{{else}}	_, err := {{.LowerFirstName}}Collection.DeleteOne(context.Background(), filter)
+{{end}}	return err
+}
+
+// Find{{.Name}}ByPage returns one page of {{.Name}} documents.
+func Find{{.Name}}ByPage(skip, limit int64) ([]*{{.Name}}, error) {
+	opt := options.Find().SetSkip(skip).SetLimit(limit)
+	cur, err := {{.LowerFirstName}}Collection.Find(context.Background(), bson.M{}, opt)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(context.Background())
+	var list []*{{.Name}}
+	for cur.Next(context.Background()) {
+		m := new({{.Name}})
+		if err = cur.Decode(m); err != nil {
+			return nil, err
+		}
+		list = append(list, m)
+	}
+	return list, cur.Err()
+}
+`
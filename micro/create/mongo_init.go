@@ -0,0 +1,49 @@
+package create
+
+func init() {
+	tplFiles["logic/model/mongo_init.gen.go"] = mongoInitFileTpl
+}
+
+// mongoInitFileTpl backs logic/model/mongo_init.gen.go: it dials the shared
+// *mongo.Database once and calls every mongo model's Init<Name>Collection
+// against it, so a project with mongo models sets up every collection and
+// its unique indexes with one call. genModelFile fills in
+// ${mongo_collection_init_list} with one Init<Name>Collection call per
+// `//go:model mongo` struct.
+const mongoInitFileTpl = `package model
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	tp "github.com/henrylee2cn/teleport"
+)
+
+// MongoConfig controls how InitMongoCollections dials the mongo cluster.
+var MongoConfig = struct {
+	URI string
+	DB  string
+}{
+	URI: "mongodb://127.0.0.1:27017",
+	DB:  "db",
+}
+
+// MongoDatabase is the shared *mongo.Database every mongo model's generated
+// CRUD functions run against, opened by InitMongoCollections.
+var MongoDatabase *mongo.Database
+
+// InitMongoCollections dials MongoConfig.URI, selects MongoConfig.DB as
+// MongoDatabase, and initializes every mongo model's collection and unique
+// indexes. No generated file calls it; it runs only where the project
+// calls it.
+func InitMongoCollections() {
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(MongoConfig.URI))
+	if err != nil {
+		tp.Fatalf("[micro] connect mongo: %v", err)
+	}
+	MongoDatabase = client.Database(MongoConfig.DB)
+${mongo_collection_init_list}
+}
+`
@@ -5,12 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"go/format"
-	"html/template"
 	"os"
 	"path"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"text/template"
 	"unsafe"
 
 	"github.com/henrylee2cn/goutil"
@@ -25,6 +26,12 @@ type (
 		codeFiles    map[string]string
 		Name         string
 		ImprotPrefix string
+		// validateTags holds each struct's `validate:"..."` tag text, keyed
+		// by struct name then field name. It's extracted straight from the
+		// raw DSL source (see parseValidateTags) since the template DSL's
+		// AST-based parser doesn't thread arbitrary tag text through to
+		// *field today.
+		validateTags map[string]map[string]string
 	}
 	Model struct {
 		*structType
@@ -42,16 +49,32 @@ type (
 		QuerySql         [2]string
 		UpdateSql        string
 		UpsertSqlSuffix  string
+		// mongo-only: a bson.M filter built from PrimaryFields, in two forms
+		// (from function arguments, and from an in-hand *Model), plus the
+		// matching argument list and whether a soft-delete field is present.
+		PrimaryArgs        string
+		PrimaryFilter      string
+		PrimaryFilterFromM string
+		HasDeletedTs       bool
 	}
 )
 
 // NewProject new project.
 func NewProject(src []byte) *Project {
+	return newProject(newTplInfo(src).Parse(), src)
+}
+
+// newProject builds a *Project from an already-parsed *tplInfo, shared by
+// NewProject (template DSL) and NewProjectFromProto (.proto IDL). src is
+// the raw IDL source; parseValidateTags reads from it the struct tags that
+// newTplInfo.Parse() doesn't expose on *field, for genValidateFile.
+func newProject(ti *tplInfo, src []byte) *Project {
 	p := new(Project)
-	p.tplInfo = newTplInfo(src).Parse()
+	p.tplInfo = ti
 	p.Name = info.ProjName()
 	p.ImprotPrefix = info.ProjPath()
 	p.codeFiles = make(map[string]string)
+	p.validateTags = parseValidateTags(src)
 	for k, v := range tplFiles {
 		p.codeFiles[k] = v
 	}
@@ -120,9 +143,10 @@ func (p *Project) Generator(force, newdoc bool) {
 		fmt.Printf("generate %s\n", realName)
 	}
 
-	// gen and write README.md
+	// gen and write README.md and api/openapi.yaml
 	if newdoc {
 		p.genAndWriteReadmeFile()
+		p.genAndWriteOpenAPIFile()
 	}
 }
 
@@ -131,6 +155,7 @@ func (p *Project) gen() {
 	p.genMainFile()
 	p.genConstFile()
 	p.genTypeFile()
+	p.genValidateFile()
 	p.genRouterFile()
 	p.genHandlerFile()
 	p.genLogicFile()
@@ -152,6 +177,165 @@ func commentToHtml(txt string) string {
 	return strings.TrimLeft(strings.Replace(txt, "// ", "<br>", -1), "<br>")
 }
 
+// genAndWriteOpenAPIFile generates and writes the api/openapi.yaml file,
+// a machine-readable counterpart to README.md.
+func (p *Project) genAndWriteOpenAPIFile() {
+	f, err := os.OpenFile("./api/openapi.yaml", os.O_WRONLY|os.O_TRUNC|os.O_CREATE, os.ModePerm)
+	if err != nil {
+		tp.Fatalf("[micro] create api/openapi.yaml error: %v", err)
+	}
+	f.WriteString(p.genOpenAPI())
+	f.Close()
+	fmt.Printf("generate %s\n", info.ProjPath()+"/api/openapi.yaml")
+}
+
+func (p *Project) genOpenAPI() string {
+	rootGroup := goutil.SnakeString(p.Name)
+	schemas := make(map[string]string)
+	var paths string
+	for _, h := range p.tplInfo.HandlerList() {
+		uri := path.Join("/", rootGroup, h.uri)
+		paths += p.openAPIPathItem(uri, h, schemas)
+	}
+	var schemaNames []string
+	for name := range schemas {
+		schemaNames = append(schemaNames, name)
+	}
+	sort.Strings(schemaNames)
+	var schemaText string
+	for _, name := range schemaNames {
+		schemaText += fmt.Sprintf("    %s:\n%s", name, schemas[name])
+	}
+
+	var text string
+	text += "openapi: 3.0.0\n"
+	text += fmt.Sprintf("info:\n  title: %s\n  version: \"1.0\"\n", info.ProjName())
+	if len(p.tplInfo.doc) > 0 {
+		text += fmt.Sprintf("  description: %s\n", yamlScalar(p.tplInfo.doc))
+	}
+	text += "paths:\n"
+	text += paths
+	text += "components:\n  schemas:\n"
+	text += schemaText
+	return text
+}
+
+func (p *Project) openAPIPathItem(uri string, h *handler, schemas map[string]string) string {
+	var text string
+	text += fmt.Sprintf("  %s:\n    post:\n", uri)
+	if len(h.doc) > 0 {
+		text += fmt.Sprintf("      summary: %s\n", yamlScalar(h.doc))
+	}
+	text += "      requestBody:\n        content:\n          application/json:\n            schema:\n"
+	text += p.openAPISchemaRef(h.arg, schemas, 14)
+	text += "      responses:\n        \"200\":\n          description: OK\n          content:\n            application/json:\n              schema:\n"
+	text += p.openAPISchemaRef(h.result, schemas, 16)
+	return text
+}
+
+// openAPISchemaRef registers the named type (recursively, via lookupTypeFields)
+// as a #/components/schemas/... entry and returns a $ref line at the given indent.
+func (p *Project) openAPISchemaRef(name string, schemas map[string]string, indent int) string {
+	p.registerOpenAPISchema(name, schemas)
+	return fmt.Sprintf("%s$ref: '#/components/schemas/%s'\n", strings.Repeat(" ", indent), name)
+}
+
+func (p *Project) registerOpenAPISchema(name string, schemas map[string]string) {
+	if _, ok := schemas[name]; ok {
+		return
+	}
+	fields, ok := p.tplInfo.lookupTypeFields(name)
+	if !ok {
+		return
+	}
+	// guard against recursive/self-referencing types while we resolve fields
+	schemas[name] = "      type: object\n"
+
+	var props string
+	for _, f := range fields {
+		fieldName := f.ModelName
+		if len(fieldName) == 0 {
+			fieldName = goutil.SnakeString(f.Name)
+		}
+		t := strings.Replace(f.Typ, "*", "", -1)
+		var isSlice bool
+		if strings.HasPrefix(t, "[]") && t != "[]byte" {
+			t = strings.TrimPrefix(t, "[]")
+			isSlice = true
+		}
+		doc := f.doc
+		if len(doc) == 0 {
+			doc = f.comment
+		}
+		doc = strings.TrimSpace(strings.Replace(doc, "\n//", "", -1))
+
+		props += fmt.Sprintf("        %s:\n", fieldName)
+		if typ, format, ok := baseTypeToOpenAPIType(t); ok {
+			if isSlice {
+				props += "          type: array\n          items:\n"
+				props += fmt.Sprintf("            type: %s\n", typ)
+				if len(format) > 0 {
+					props += fmt.Sprintf("            format: %s\n", format)
+				}
+			} else {
+				props += fmt.Sprintf("          type: %s\n", typ)
+				if len(format) > 0 {
+					props += fmt.Sprintf("          format: %s\n", format)
+				}
+			}
+		} else {
+			p.registerOpenAPISchema(t, schemas)
+			if isSlice {
+				props += fmt.Sprintf("          type: array\n          items:\n            $ref: '#/components/schemas/%s'\n", t)
+			} else {
+				props += fmt.Sprintf("          $ref: '#/components/schemas/%s'\n", t)
+			}
+		}
+		if len(doc) > 0 {
+			props += fmt.Sprintf("          description: %s\n", yamlScalar(doc))
+		}
+	}
+	schemas[name] = fmt.Sprintf("      type: object\n      properties:\n%s", props)
+}
+
+// baseTypeToOpenAPIType maps a Go base type to an OpenAPI (type, format) pair,
+// analogous to baseTypeToJsonValue.
+func baseTypeToOpenAPIType(t string) (typ, format string, ok bool) {
+	switch t {
+	case "bool":
+		return "boolean", "", true
+	case "string":
+		return "string", "", true
+	case "[]byte":
+		return "string", "byte", true
+	case "time.Time":
+		return "string", "date-time", true
+	case "int8", "int16", "int32":
+		return "integer", "int32", true
+	case "int", "int64":
+		return "integer", "int64", true
+	case "uint8", "byte", "uint16", "uint32":
+		return "integer", "int32", true
+	case "uint", "uint64":
+		return "integer", "int64", true
+	case "float32":
+		return "number", "float", true
+	case "float64":
+		return "number", "double", true
+	case "rune":
+		return "integer", "int32", true
+	}
+	return "", "", false
+}
+
+// yamlScalar renders a doc comment as a quoted YAML scalar on a single line.
+func yamlScalar(txt string) string {
+	txt = strings.TrimSpace(strings.Replace(txt, "\n", " ", -1))
+	txt = strings.TrimSpace(strings.Replace(txt, "// ", "", -1))
+	b, _ := json.Marshal(txt)
+	return string(b)
+}
+
 func (p *Project) genReadme() string {
 	var text string
 	text += commentToHtml(p.tplInfo.doc)
@@ -281,11 +465,14 @@ func (p *Project) genConstFile() {
 	var text string
 	for _, s := range p.tplInfo.models.mysql {
 		name := s.name + "Sql"
+		// the DDL text is full of backtick-quoted identifiers, so it can't
+		// be wrapped in a raw-string literal (the first backtick would end
+		// it early) — use strconv.Quote for a valid, safely-escaped literal
 		text += fmt.Sprintf(
 			"// %s the statement to create '%s' mysql table\n"+
-				"const %s string = ``\n",
+				"const %s string = %s\n",
 			name, goutil.SnakeString(s.name),
-			name,
+			name, strconv.Quote(ddlSQLByName[s.name]),
 		)
 	}
 	p.replaceWithLine("args/const.gen.go", "${const_list}", text)
@@ -311,7 +498,14 @@ func (p *Project) genHandlerFile() {
 			if len(h.group.name) > 0 {
 				ctx = firstLowerLetter(h.group.name) + ".PushCtx"
 			}
-			return fmt.Sprintf("return logic.%s(%s, arg)", h.fullName, ctx)
+			return fmt.Sprintf(
+				"_start := startTimer()\n"+
+					"if rerr := arg.Validate(); rerr != nil {\nrecordMetrics(%q, rerr, _start)\nreturn rerr\n}\n"+
+					"rerr := logic.%s(%s, arg)\n"+
+					"recordMetrics(%q, rerr, _start)\n"+
+					"return rerr",
+				h.fullName, h.fullName, ctx, h.fullName,
+			)
 		})
 		p.replaceWithLine("api/push_handler.gen.go", "${handler_api_define}", s)
 	} else {
@@ -324,7 +518,14 @@ func (p *Project) genHandlerFile() {
 			if len(h.group.name) > 0 {
 				ctx = firstLowerLetter(h.group.name) + ".PullCtx"
 			}
-			return fmt.Sprintf("return logic.%s(%s, arg)", h.fullName, ctx)
+			return fmt.Sprintf(
+				"_start := startTimer()\n"+
+					"if rerr := arg.Validate(); rerr != nil {\nrecordMetrics(%q, rerr, _start)\nreturn nil, rerr\n}\n"+
+					"result, rerr := logic.%s(%s, arg)\n"+
+					"recordMetrics(%q, rerr, _start)\n"+
+					"return result, rerr",
+				h.fullName, h.fullName, ctx, h.fullName,
+			)
 		})
 		p.replaceWithLine("api/pull_handler.gen.go", "${handler_api_define}", s)
 	} else {
@@ -402,11 +603,18 @@ func (p *Project) genModelFile() {
 		p.codeFiles[fileName] = newModelString(m)
 		p.fillFile(fileName)
 	}
+	var mongoInits string
 	for _, m := range p.tplInfo.models.mongo {
 		fileName := "logic/model/mongo_" + goutil.SnakeString(m.name) + ".gen.go"
 		p.codeFiles[fileName] = newModelString(m)
 		p.fillFile(fileName)
+		mongoInits += fmt.Sprintf(
+			"\tif err := Init%sCollection(MongoDatabase); err != nil {\n"+
+				"\t\ttp.Fatalf(\"[micro] init '%s' mongo collection: %%v\", err)\n\t}\n",
+			m.name, goutil.SnakeString(m.name),
+		)
 	}
+	p.replaceWithLine("logic/model/mongo_init.gen.go", "${mongo_collection_init_list}", mongoInits)
 }
 
 func newModelString(s *structType) string {
@@ -433,39 +641,24 @@ func newModelString(s *structType) string {
 }
 
 func (mod *Model) mongoString() string {
-	mod.NameSql = fmt.Sprintf("`%s`", mod.SnakeName)
-	mod.QuerySql = [2]string{}
-	mod.UpdateSql = ""
-	mod.UpsertSqlSuffix = ""
-
-	var (
-		fields               []string
-		querySql1, querySql2 string
-	)
-	for _, field := range mod.fields {
-		fields = append(fields, field.ModelName)
-	}
-	var primaryFields []string
-	var primaryFieldMap = make(map[string]bool)
-	for _, field := range mod.PrimaryFields {
-		primaryFields = append(primaryFields, field.ModelName)
-		primaryFieldMap[field.ModelName] = true
-	}
-	for _, field := range fields {
-		if field == "deleted_ts" || primaryFieldMap[field] {
-			continue
-		}
-		querySql1 += fmt.Sprintf("`%s`,", field)
-		querySql2 += fmt.Sprintf(":%s,", field)
-		if field == "created_at" {
-			continue
+	var primaryArgs, primaryFilter, primaryFilterFromM string
+	for _, f := range mod.PrimaryFields {
+		argName := strings.ToLower(f.Name[:1]) + f.Name[1:]
+		primaryArgs += fmt.Sprintf("%s %s, ", argName, f.Typ)
+		primaryFilter += fmt.Sprintf("\"%s\": %s, ", f.ModelName, argName)
+		primaryFilterFromM += fmt.Sprintf("\"%s\": m.%s, ", f.ModelName, f.Name)
+	}
+	mod.PrimaryArgs = strings.TrimSuffix(primaryArgs, ", ")
+	mod.PrimaryFilter = strings.TrimSuffix(primaryFilter, ", ")
+	mod.PrimaryFilterFromM = strings.TrimSuffix(primaryFilterFromM, ", ")
+
+	mod.HasDeletedTs = false
+	for _, f := range mod.fields {
+		if f.ModelName == "deleted_ts" {
+			mod.HasDeletedTs = true
+			break
 		}
-		mod.UpdateSql += fmt.Sprintf("`%s`=:%s,", field, field)
-		mod.UpsertSqlSuffix += fmt.Sprintf("`%s`=VALUES(`%s`),", field, field)
 	}
-	mod.QuerySql = [2]string{querySql1[:len(querySql1)-1], querySql2[:len(querySql2)-1]}
-	mod.UpdateSql = mod.UpdateSql[:len(mod.UpdateSql)-1]
-	mod.UpsertSqlSuffix = mod.UpsertSqlSuffix[:len(mod.UpsertSqlSuffix)-1] + ";"
 
 	m, err := template.New("").Parse(mongoModelTpl)
 	if err != nil {
@@ -0,0 +1,140 @@
+package create
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/henrylee2cn/goutil"
+	tp "github.com/henrylee2cn/teleport"
+)
+
+// NewProjectFromProto is like NewProject but parses a proto3 IDL (a `.proto`
+// file) instead of the hand-rolled template DSL understood by newTplInfo.
+// The resulting *tplInfo feeds genMainFile/genRouterFile/genModelFile exactly
+// like the one produced from the template DSL.
+func NewProjectFromProto(src []byte) *Project {
+	return newProject(newProtoTplInfo(src), src)
+}
+
+// NewProjectFromFile is the CLI's entry point: it detects the IDL format by
+// srcPath's extension and runs the matching parser (.proto vs the template
+// DSL), then feeds the result into NewProject/NewProjectFromProto unchanged.
+func NewProjectFromFile(srcPath string, src []byte) *Project {
+	if strings.EqualFold(filepath.Ext(srcPath), ".proto") {
+		return NewProjectFromProto(src)
+	}
+	return NewProject(src)
+}
+
+var (
+	protoMessageRe  = regexp.MustCompile(`(?s)message\s+(\w+)\s*\{(.*?)\n\}`)
+	protoFieldRe    = regexp.MustCompile(`^\s*(repeated\s+)?(\S+)\s+(\w+)\s*=\s*\d+\s*(\[[^\]]*\])?\s*;\s*(//\s*(.*))?$`)
+	protoServiceRe  = regexp.MustCompile(`(?s)service\s+(\w+)\s*\{(.*?)\n\}`)
+	protoRpcRe      = regexp.MustCompile(`rpc\s+(\w+)\s*\(\s*(stream\s+)?(\w+)\s*\)\s*returns\s*\(\s*(stream\s+)?(\w+)\s*\)\s*(?:\{([^}]*)\}|;)`)
+	protoUriOptRe   = regexp.MustCompile(`\(micro\.uri\)\s*=\s*"([^"]*)"`)
+	protoModelOptRe = regexp.MustCompile(`\(micro\.model\)\s*=\s*"([^"]*)"`)
+)
+
+// protoTypeToGoType maps proto3 scalar types onto the Go types the template
+// DSL already uses, so the rest of the generator never has to know a type
+// came from a .proto file.
+var protoTypeToGoType = map[string]string{
+	"double": "float64", "float": "float32",
+	"int32": "int32", "int64": "int64",
+	"uint32": "uint32", "uint64": "uint64",
+	"sint32": "int32", "sint64": "int64",
+	"fixed32": "uint32", "fixed64": "uint64",
+	"sfixed32": "int32", "sfixed64": "int64",
+	"bool": "bool", "string": "string", "bytes": "[]byte",
+}
+
+// newProtoTplInfo parses a proto3 schema into a *tplInfo equivalent to the
+// one newTplInfo(src).Parse() produces from the template DSL: messages
+// become types, and `service { rpc Foo(FooReq) returns (FooResp); }` becomes
+// a handler with arg=FooReq, result=FooResp.
+func newProtoTplInfo(src []byte) *tplInfo {
+	text := string(src)
+	ti := new(tplInfo)
+	ti.types = make(map[string]*structType)
+
+	for _, m := range protoMessageRe.FindAllStringSubmatch(text, -1) {
+		name, body := m[1], m[2]
+		s := &structType{name: name, isDefaultPrimary: true}
+		for _, line := range strings.Split(body, "\n") {
+			fm := protoFieldRe.FindStringSubmatch(line)
+			if fm == nil {
+				continue
+			}
+			repeated, protoTyp, fname, comment := fm[1], fm[2], fm[3], fm[6]
+			goTyp, ok := protoTypeToGoType[protoTyp]
+			if !ok {
+				// not a scalar: reference to another message, resolved once
+				// every message in the file has been registered
+				goTyp = protoTyp
+			}
+			if len(repeated) > 0 {
+				goTyp = "[]" + goTyp
+			}
+			s.fields = append(s.fields, &field{
+				Name:      goutil.CamelString(fname),
+				ModelName: fname,
+				Typ:       goTyp,
+				comment:   comment,
+			})
+		}
+		ti.types[name] = s
+	}
+
+	// Reject recursive message graphs before they reach code generation and
+	// panic, mirroring the visited-set walk used by gogoproto's plugin loop
+	// check.
+	for name := range ti.types {
+		checkProtoFieldLoop(ti.types, name, map[string]bool{})
+	}
+
+	for _, sm := range protoServiceRe.FindAllStringSubmatch(text, -1) {
+		for _, rm := range protoRpcRe.FindAllStringSubmatch(sm[2], -1) {
+			rpcName, clientStream, arg, serverStream, result, opts := rm[1], rm[2], rm[3], rm[4], rm[5], rm[6]
+			h := &handler{fullName: rpcName, arg: arg, result: result}
+			if len(clientStream) > 0 || len(serverStream) > 0 {
+				h.group.typ = pushType
+			} else {
+				h.group.typ = pullType
+			}
+			if um := protoUriOptRe.FindStringSubmatch(opts); um != nil {
+				h.uri = um[1]
+			} else {
+				h.uri = "/" + goutil.SnakeString(rpcName)
+			}
+			if mm := protoModelOptRe.FindStringSubmatch(opts); mm != nil {
+				if s, ok := ti.types[arg]; ok {
+					s.modelStyle = mm[1]
+				}
+			}
+			ti.handlers = append(ti.handlers, h)
+		}
+	}
+	return ti
+}
+
+// checkProtoFieldLoop walks message field references the same way
+// gogoproto's plugin loop check does, failing fast via tp.Fatalf on a cycle
+// instead of letting it panic deep inside code generation.
+func checkProtoFieldLoop(types map[string]*structType, name string, visited map[string]bool) {
+	if visited[name] {
+		tp.Fatalf("[micro] proto: message %q is recursive, which genModelFile/genTypeFile cannot represent", name)
+	}
+	s, ok := types[name]
+	if !ok {
+		return
+	}
+	visited[name] = true
+	for _, f := range s.fields {
+		t := strings.TrimPrefix(f.Typ, "[]")
+		if _, isMessage := types[t]; isMessage {
+			checkProtoFieldLoop(types, t, visited)
+		}
+	}
+	delete(visited, name)
+}
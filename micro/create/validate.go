@@ -0,0 +1,202 @@
+package create
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	tplFiles["args/validate.gen.go"] = validateFileTpl
+}
+
+const validateFileTpl = `package args
+
+import (
+	"regexp"
+
+	tp "github.com/henrylee2cn/teleport"
+)
+${validate_define_list}
+`
+
+var (
+	validateStructRe = regexp.MustCompile(`(?s)type\s+(\w+)\s+struct\s*\{(.*?)\n\}`)
+	validateFieldRe  = regexp.MustCompile("(?m)^\\s*(\\w+)\\s+[][*\\w.]+\\s*`[^`]*validate:\"([^\"]*)\"[^`]*`")
+)
+
+// parseValidateTags scans the raw DSL source for struct field tags of the
+// form `validate:"..."` and returns them keyed by struct name then field
+// name. The template DSL is literal Go syntax parsed into *tplInfo/*field
+// by newTplInfo, which doesn't thread arbitrary tag text through to
+// *field today, so genValidateFile reads the tags straight from source
+// instead of from the parsed field.
+func parseValidateTags(src []byte) map[string]map[string]string {
+	tags := make(map[string]map[string]string)
+	for _, sm := range validateStructRe.FindAllStringSubmatch(string(src), -1) {
+		structName, body := sm[1], sm[2]
+		for _, fm := range validateFieldRe.FindAllStringSubmatch(body, -1) {
+			fieldName, tag := fm[1], fm[2]
+			if tags[structName] == nil {
+				tags[structName] = make(map[string]string)
+			}
+			tags[structName][fieldName] = tag
+		}
+	}
+	return tags
+}
+
+// validateShortcutRegexp expands well-known validate tag shortcuts into
+// canned regexps, mirroring the required/min-items validation-plugin
+// pattern from gogoproto validators.
+var validateShortcutRegexp = map[string]string{
+	"email": `^[\w.+-]+@[\w-]+\.[a-zA-Z]{2,}$`,
+	"url":   `^https?://[^\s]+$`,
+	"uuid":  `^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`,
+}
+
+// genValidateFile emits args/validate.gen.go: a `Validate() *tp.Rerror`
+// method on every request type reachable from a handler, derived from each
+// field's `validate:"..."` struct tag.
+func (p *Project) genValidateFile() {
+	var regexpVars, methods string
+	seen := make(map[string]bool)
+	for _, h := range p.tplInfo.HandlerList() {
+		methods += p.validateMethod(h.arg, seen, &regexpVars)
+	}
+	p.replaceWithLine("args/validate.gen.go", "${validate_define_list}", regexpVars+methods)
+}
+
+// validateMethod emits `func (a *<name>) Validate() *tp.Rerror` for the
+// named type, recursing into struct-typed fields so nested args validate
+// themselves too. seen memoizes so a type shared by multiple handlers is
+// only emitted once.
+func (p *Project) validateMethod(name string, seen map[string]bool, regexpVars *string) string {
+	if seen[name] {
+		return ""
+	}
+	seen[name] = true
+	fields, ok := p.tplInfo.lookupTypeFields(name)
+	if !ok {
+		return ""
+	}
+	var body, nested string
+	for _, f := range fields {
+		body += p.validateField(name, f, regexpVars)
+		t := strings.TrimPrefix(strings.Replace(f.Typ, "*", "", -1), "[]")
+		if _, isBaseType := baseTypeToJsonValue(t); !isBaseType {
+			body += validateNestedCall(f)
+			nested += p.validateMethod(t, seen, regexpVars)
+		}
+	}
+	return fmt.Sprintf(
+		"// Validate checks the fields of %s against their validate tags.\n"+
+			"func (a *%s) Validate() *tp.Rerror {\n%s\treturn nil\n}\n\n%s",
+		name, name, body, nested,
+	)
+}
+
+// validateNestedCall emits the call into a nested struct-typed field's own
+// Validate() method, so "Nested struct fields recursively call the child
+// Validate()" actually happens instead of just generating dead code for it.
+func validateNestedCall(f *field) string {
+	typ := strings.Replace(f.Typ, "*", "", -1)
+	switch {
+	case strings.HasPrefix(typ, "[]"):
+		return fmt.Sprintf(
+			"\tfor _, _e := range a.%s {\n\t\tif rerr := _e.Validate(); rerr != nil {\n\t\t\treturn rerr\n\t\t}\n\t}\n",
+			f.Name,
+		)
+	case strings.HasPrefix(f.Typ, "*"):
+		return fmt.Sprintf(
+			"\tif a.%s != nil {\n\t\tif rerr := a.%s.Validate(); rerr != nil {\n\t\t\treturn rerr\n\t\t}\n\t}\n",
+			f.Name, f.Name,
+		)
+	default:
+		return fmt.Sprintf("\tif rerr := a.%s.Validate(); rerr != nil {\n\t\treturn rerr\n\t}\n", f.Name)
+	}
+}
+
+// wrapPtrNilGuard guards a pointer numeric field's dereferencing check with
+// a nil check, so min=/max= on a *int32 etc. doesn't panic on a nil pointer
+// that wasn't also tagged required.
+func wrapPtrNilGuard(isPtr bool, goName, check string) string {
+	if !isPtr {
+		return check
+	}
+	return fmt.Sprintf("\tif a.%s != nil {\n\t%s\t}\n", goName, strings.Replace(check, "\n", "\n\t", -1))
+}
+
+func (p *Project) validateField(typeName string, f *field, regexpVars *string) string {
+	tag := p.validateTags[typeName][f.Name]
+	if len(tag) == 0 {
+		return ""
+	}
+	goName := f.Name
+	typ := strings.Replace(f.Typ, "*", "", -1)
+	isPtr := strings.HasPrefix(f.Typ, "*")
+	isSlice := strings.HasPrefix(typ, "[]") && typ != "[]byte"
+	isString := typ == "string"
+	isNumeric := !isString && typ != "[]byte" && typ != "bool" && typ != "time.Time" && !isSlice
+	// a pointer numeric field still needs min=/max= enforced on the pointee;
+	// ref is what the generated comparisons dereference, guarded by the
+	// required-or-not-nil check below so a nil pointer never gets dereferenced
+	ref := "a." + goName
+	if isPtr && isNumeric {
+		ref = "*a." + goName
+	}
+
+	var text string
+	var required, min, max, pattern string
+	// Rules are split on every comma, escaped or not, so a regexp= pattern
+	// containing a comma (e.g. `regexp=^[a-z,]+$`) is cut at its first
+	// comma.
+	for _, rule := range strings.Split(tag, ",") {
+		switch rule = strings.TrimSpace(rule); {
+		case rule == "required":
+			required = rule
+		case strings.HasPrefix(rule, "min="):
+			min = strings.TrimPrefix(rule, "min=")
+		case strings.HasPrefix(rule, "max="):
+			max = strings.TrimPrefix(rule, "max=")
+		case strings.HasPrefix(rule, "regexp="):
+			pattern = strings.TrimPrefix(rule, "regexp=")
+		case rule == "email", rule == "url", rule == "uuid":
+			pattern = validateShortcutRegexp[rule]
+		}
+	}
+
+	if len(required) > 0 {
+		switch {
+		case isPtr:
+			text += fmt.Sprintf("\tif a.%s == nil {\n\t\treturn tp.NewRerror(400, \"INVALID_PARAM\", \"%s is required\")\n\t}\n", goName, goName)
+		case isString, isSlice:
+			text += fmt.Sprintf("\tif len(a.%s) == 0 {\n\t\treturn tp.NewRerror(400, \"INVALID_PARAM\", \"%s is required\")\n\t}\n", goName, goName)
+		case isNumeric:
+			text += fmt.Sprintf("\tif a.%s == 0 {\n\t\treturn tp.NewRerror(400, \"INVALID_PARAM\", \"%s is required\")\n\t}\n", goName, goName)
+		}
+	}
+	if len(min) > 0 {
+		switch {
+		case isString, isSlice:
+			text += fmt.Sprintf("\tif len(a.%s) < %s {\n\t\treturn tp.NewRerror(400, \"INVALID_PARAM\", \"%s must have a minimum length of %s\")\n\t}\n", goName, min, goName, min)
+		case isNumeric:
+			text += wrapPtrNilGuard(isPtr, goName, fmt.Sprintf("\tif %s < %s {\n\t\treturn tp.NewRerror(400, \"INVALID_PARAM\", \"%s must be at least %s\")\n\t}\n", ref, min, goName, min))
+		}
+	}
+	if len(max) > 0 {
+		switch {
+		case isString, isSlice:
+			text += fmt.Sprintf("\tif len(a.%s) > %s {\n\t\treturn tp.NewRerror(400, \"INVALID_PARAM\", \"%s must have a maximum length of %s\")\n\t}\n", goName, max, goName, max)
+		case isNumeric:
+			text += wrapPtrNilGuard(isPtr, goName, fmt.Sprintf("\tif %s > %s {\n\t\treturn tp.NewRerror(400, \"INVALID_PARAM\", \"%s must be at most %s\")\n\t}\n", ref, max, goName, max))
+		}
+	}
+	if len(pattern) > 0 && isString {
+		reVar := fmt.Sprintf("_%s%sRe", strings.ToLower(typeName[:1])+typeName[1:], goName)
+		*regexpVars += fmt.Sprintf("var %s = regexp.MustCompile(%s)\n\n", reVar, strconv.Quote(pattern))
+		text += fmt.Sprintf("\tif !%s.MatchString(a.%s) {\n\t\treturn tp.NewRerror(400, \"INVALID_PARAM\", \"%s is invalid\")\n\t}\n", reVar, goName, goName)
+	}
+	return text
+}
@@ -0,0 +1,47 @@
+package create
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseValidateTags(t *testing.T) {
+	src := []byte(`
+type LoginArgs struct {
+	Username string ` + "`validate:\"required,min=3,max=32\"`" + `
+	Password string ` + "`validate:\"required\"`" + `
+	Age      int    ` + "`json:\"age\"`" + `
+}
+`)
+	tags := parseValidateTags(src)
+	got, ok := tags["LoginArgs"]
+	if !ok {
+		t.Fatalf("expected LoginArgs to have validate tags, got none")
+	}
+	if got["Username"] != "required,min=3,max=32" {
+		t.Errorf("Username tag = %q, want %q", got["Username"], "required,min=3,max=32")
+	}
+	if got["Password"] != "required" {
+		t.Errorf("Password tag = %q, want %q", got["Password"], "required")
+	}
+	if _, ok := got["Age"]; ok {
+		t.Errorf("Age should have no validate tag, got %q", got["Age"])
+	}
+}
+
+func TestValidateFieldEmitsNonTrivialCheck(t *testing.T) {
+	p := &Project{
+		validateTags: map[string]map[string]string{
+			"LoginArgs": {"Username": "required,min=3,max=32"},
+		},
+	}
+	var regexpVars string
+	f := &field{Name: "Username", Typ: "string"}
+	got := p.validateField("LoginArgs", f, &regexpVars)
+	if got == "" {
+		t.Fatalf("validateField() = %q, want a non-empty Validate() body for a required tagged field", got)
+	}
+	if !strings.Contains(got, "a.Username") {
+		t.Errorf("validateField() = %q, want a check referencing a.Username", got)
+	}
+}